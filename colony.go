@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// computeStep applies t's rule for the cell it currently stands on --
+// updating its state and marking the field -- and returns the (x,y) it
+// would walk to next, without moving it there yet. Splitting sensing/
+// marking from committing the move lets Colony.Step resolve collisions
+// between turmites before any of them actually relocate.
+func (t *Turmite) computeStep(field Field) (int, int, error) {
+	// field is the input,which would store the board
+
+	// sense the color and find the suitable rules ==> obtain the signal based on the current location
+	currState := t.state
+	currColor := field[t.x][t.y]
+
+	currSignal := Signal{
+		state: currState,
+		color: currColor,
+	}
+
+	// find the rules based on the signal
+	nextAction := t.rules[currSignal]
+
+	// set its state to a new value in a...z.
+	t.state = nextAction.state
+
+	// change the color of the square that it is on to some color
+	field[t.x][t.y] = nextAction.color
+
+	// Turn degrees relative to the direction it is facing
+	degrees := nextAction.turn
+
+	// obtain the existing direction
+	currFace := t.face
+
+	// A mirror device on the current cell reflects the turmite's heading
+	// regardless of the rule table; DeviceBeforeRule applies that before the
+	// rule's own turn is taken, DeviceAfterRule applies it after.
+	device, hasDevice := t.tiles[Position{t.x, t.y}]
+	if hasDevice && t.deviceOrder == DeviceBeforeRule {
+		currFace = reflectFace(currFace, device.Kind)
+	}
+
+	switch currFace {
+	case "North":
+		t.NorthMove(degrees)
+	case "South":
+		t.SouthMove(degrees)
+	case "East":
+		t.EastMove(degrees)
+	case "West":
+		t.WestMove(degrees)
+	}
+	//t.currentDir = nextAction.turn
+
+	if hasDevice && t.deviceOrder == DeviceAfterRule {
+		t.face = reflectFace(t.face, device.Kind)
+	}
+
+	// Walk one step in the direction it is facing, letting the topology
+	// decide what happens if that step would leave the field.
+	topology := t.topology
+	if topology == nil {
+		topology = TorusTopology{}
+	}
+	nx, ny, newFace, err := topology.Step(len(field), t.x, t.y, t.face)
+	if err != nil {
+		return t.x, t.y, err
+	}
+	t.face = newFace
+
+	// A teleporter on the cell the turmite is arriving at jumps it straight
+	// to its paired cell, preserving heading.
+	if dest, ok := t.tiles[Position{nx, ny}]; ok && dest.Kind == DeviceTeleporter {
+		nx, ny = dest.TeleportX, dest.TeleportY
+	}
+
+	return nx, ny, nil
+}
+
+func (t *Turmite) NorthMove(degrees Direction) {
+	if degrees == 0 {
+		t.face = "North"
+	} else if degrees == 1 {
+		t.face = "East"
+	} else if degrees == 2 {
+		t.face = "South"
+	} else {
+		t.face = "West"
+	}
+
+}
+func (t *Turmite) SouthMove(degrees Direction) {
+	if degrees == 0 {
+		t.face = "South"
+	} else if degrees == 1 {
+		t.face = "West"
+	} else if degrees == 2 {
+		t.face = "North"
+	} else {
+		t.face = "East"
+	}
+}
+func (t *Turmite) EastMove(degrees Direction) {
+
+	if degrees == 0 {
+		t.face = "East"
+	} else if degrees == 1 {
+		t.face = "South"
+	} else if degrees == 2 {
+		t.face = "West"
+	} else {
+		t.face = "North"
+	}
+}
+func (t *Turmite) WestMove(degrees Direction) {
+
+	if degrees == 0 {
+		t.face = "West"
+	} else if degrees == 1 {
+		t.face = "North"
+	} else if degrees == 2 {
+		t.face = "East"
+	} else {
+		t.face = "South"
+	}
+}
+
+// CollisionMode controls what happens when two or more turmites in a Colony
+// try to move onto the same cell in the same tick.
+type CollisionMode int
+
+const (
+	// CollisionIgnore lets turmites freely share a cell.
+	CollisionIgnore CollisionMode = iota
+	// CollisionBlock keeps every colliding turmite at its current position
+	// instead of letting any of them move onto the contested cell.
+	CollisionBlock
+	// CollisionAnnihilate removes every turmite involved in the collision
+	// from the colony.
+	CollisionAnnihilate
+	// CollisionMerge keeps only the first (lowest-index) turmite involved in
+	// the collision; the rest are removed.
+	CollisionMerge
+)
+
+// CollisionModeFromString returns a CollisionMode constant given an English
+// string.
+func CollisionModeFromString(s string) (CollisionMode, error) {
+	switch strings.ToLower(s) {
+	case "ignore":
+		return CollisionIgnore, nil
+	case "block":
+		return CollisionBlock, nil
+	case "annihilate":
+		return CollisionAnnihilate, nil
+	case "merge":
+		return CollisionMerge, nil
+	default:
+		return 0, fmt.Errorf("unknown collision mode: %s", s)
+	}
+}
+
+// OwnerGrid records, for each field cell, the id of the turmite that last
+// wrote to it (or -1 if no turmite has written there yet),
+// so that Field.DrawFieldTinted can color cells by author.
+type OwnerGrid [][]int
+
+// NewOwnerGrid creates a square OwnerGrid of the given edge size, with every
+// cell starting unowned.
+func NewOwnerGrid(size int) OwnerGrid {
+	g := make(OwnerGrid, size)
+	for i := range g {
+		g[i] = make([]int, size)
+		for j := range g[i] {
+			g[i][j] = -1
+		}
+	}
+	return g
+}
+
+// Colony is a group of turmites sharing a single field.
+type Colony []*Turmite
+
+// Step advances every turmite in the colony by one tick, resolving any
+// turmites that want to move onto the same cell according to mode. Owners,
+// if non-nil, is updated with the id of each turmite on the cell it marks
+// this tick. Step returns the colony that survives the tick:
+// under CollisionAnnihilate and CollisionMerge this may be shorter than c.
+func (c Colony) Step(field Field, mode CollisionMode, owners OwnerGrid) (Colony, error) {
+	type intent struct {
+		x, y int
+	}
+
+	intents := make([]intent, len(c))
+	for i, t := range c {
+		// computeStep marks the cell t currently stands on.
+		if owners != nil {
+			owners[t.x][t.y] = t.id
+		}
+		nx, ny, err := t.computeStep(field)
+		if err != nil {
+			return nil, err
+		}
+		intents[i] = intent{nx, ny}
+	}
+
+	// A beam-splitter device on the cell a turmite is leaving overrides its
+	// intended move: the turmite continues as one perpendicular beam, and a
+	// cloned turmite spawns as the other. Splitters require a Colony to
+	// spawn into, so -- unlike mirrors and teleporters -- they can't be
+	// handled inside Turmite.computeStep.
+	nextID := 0
+	for _, t := range c {
+		if t.id >= nextID {
+			nextID = t.id + 1
+		}
+	}
+	var spawned Colony
+	var spawnIntents []intent
+	for i, t := range c {
+		device, ok := t.tiles[Position{t.x, t.y}]
+		if !ok {
+			continue
+		}
+		axis1, axis2 := splitAxes(device.Kind, t.face)
+		if axis1 == "" {
+			continue
+		}
+
+		topology := t.topology
+		if topology == nil {
+			topology = TorusTopology{}
+		}
+		nx1, ny1, face1, err := topology.Step(len(field), t.x, t.y, axis1)
+		if err != nil {
+			return nil, err
+		}
+		nx2, ny2, face2, err := topology.Step(len(field), t.x, t.y, axis2)
+		if err != nil {
+			return nil, err
+		}
+
+		t.face = face1
+		intents[i] = intent{nx1, ny1}
+
+		clone := *t
+		clone.id = nextID
+		nextID++
+		clone.face = face2
+		spawned = append(spawned, &clone)
+		spawnIntents = append(spawnIntents, intent{nx2, ny2})
+	}
+
+	// Group turmite indices by the cell they want to move into. Splitter
+	// spawns are appended after the surviving colony's own intents and share
+	// the same claimant map, so two spawns (or a spawn and a survivor)
+	// landing on the same cell are resolved by mode exactly like any other
+	// collision.
+	allIntents := append(append([]intent{}, intents...), spawnIntents...)
+	claimants := make(map[intent][]int)
+	for i, in := range allIntents {
+		claimants[in] = append(claimants[in], i)
+	}
+
+	removed := make([]bool, len(allIntents))
+	if mode != CollisionIgnore {
+		for _, indices := range claimants {
+			if len(indices) < 2 {
+				continue
+			}
+			switch mode {
+			case CollisionBlock:
+				for _, i := range indices {
+					if i < len(c) {
+						allIntents[i] = intent{c[i].x, c[i].y}
+					} else {
+						s := spawned[i-len(c)]
+						allIntents[i] = intent{s.x, s.y}
+					}
+				}
+			case CollisionAnnihilate:
+				for _, i := range indices {
+					removed[i] = true
+				}
+			case CollisionMerge:
+				for _, i := range indices[1:] {
+					removed[i] = true
+				}
+			}
+		}
+	}
+
+	var survivors Colony
+	for i, t := range c {
+		if removed[i] {
+			continue
+		}
+		t.x, t.y = allIntents[i].x, allIntents[i].y
+		survivors = append(survivors, t)
+	}
+
+	for i, t := range spawned {
+		j := len(c) + i
+		if removed[j] {
+			continue
+		}
+		t.x, t.y = allIntents[j].x, allIntents[j].y
+		survivors = append(survivors, t)
+	}
+
+	return survivors, nil
+}
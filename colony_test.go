@@ -0,0 +1,95 @@
+package main
+
+import "testing"
+
+// forwardRules is a minimal rule table that always moves a turmite straight
+// ahead without turning or changing color, so its intended destination is
+// easy to predict for collision tests.
+func forwardRules() map[Signal]Action {
+	return map[Signal]Action{
+		{state: 0, color: 0}: {state: 0, color: 0, turn: ForwardDir},
+	}
+}
+
+// headOnColony builds two turmites on a 5x5 field walking straight toward
+// each other, so they both want to move onto (1,0) on the next Step.
+func headOnColony() (Field, Colony) {
+	field := NewField(5)
+	t1 := &Turmite{rules: forwardRules(), x: 0, y: 0, face: "East", id: 0}
+	t2 := &Turmite{rules: forwardRules(), x: 2, y: 0, face: "West", id: 1}
+	return field, Colony{t1, t2}
+}
+
+func TestColonyStepIgnore(t *testing.T) {
+	field, colony := headOnColony()
+	survivors, err := colony.Step(field, CollisionIgnore, nil)
+	if err != nil {
+		t.Fatalf("Step: unexpected error: %v", err)
+	}
+	if len(survivors) != 2 {
+		t.Fatalf("CollisionIgnore: got %d survivors, want 2", len(survivors))
+	}
+	for _, s := range survivors {
+		if s.x != 1 || s.y != 0 {
+			t.Errorf("CollisionIgnore: turmite %d at (%d,%d), want (1,0)", s.id, s.x, s.y)
+		}
+	}
+}
+
+func TestColonyStepBlock(t *testing.T) {
+	field, colony := headOnColony()
+	survivors, err := colony.Step(field, CollisionBlock, nil)
+	if err != nil {
+		t.Fatalf("Step: unexpected error: %v", err)
+	}
+	if len(survivors) != 2 {
+		t.Fatalf("CollisionBlock: got %d survivors, want 2", len(survivors))
+	}
+	want := map[int][2]int{0: {0, 0}, 1: {2, 0}}
+	for _, s := range survivors {
+		if s.x != want[s.id][0] || s.y != want[s.id][1] {
+			t.Errorf("CollisionBlock: turmite %d at (%d,%d), want %v", s.id, s.x, s.y, want[s.id])
+		}
+	}
+}
+
+func TestColonyStepAnnihilate(t *testing.T) {
+	field, colony := headOnColony()
+	survivors, err := colony.Step(field, CollisionAnnihilate, nil)
+	if err != nil {
+		t.Fatalf("Step: unexpected error: %v", err)
+	}
+	if len(survivors) != 0 {
+		t.Fatalf("CollisionAnnihilate: got %d survivors, want 0", len(survivors))
+	}
+}
+
+func TestColonyStepMerge(t *testing.T) {
+	field, colony := headOnColony()
+	survivors, err := colony.Step(field, CollisionMerge, nil)
+	if err != nil {
+		t.Fatalf("Step: unexpected error: %v", err)
+	}
+	if len(survivors) != 1 {
+		t.Fatalf("CollisionMerge: got %d survivors, want 1", len(survivors))
+	}
+	if survivors[0].id != 0 {
+		t.Errorf("CollisionMerge: survivor id = %d, want 0 (lowest index)", survivors[0].id)
+	}
+	if survivors[0].x != 1 || survivors[0].y != 0 {
+		t.Errorf("CollisionMerge: survivor at (%d,%d), want (1,0)", survivors[0].x, survivors[0].y)
+	}
+}
+
+func TestColonyStepUpdatesOwnerGrid(t *testing.T) {
+	field, colony := headOnColony()
+	owners := NewOwnerGrid(5)
+	if _, err := colony.Step(field, CollisionIgnore, owners); err != nil {
+		t.Fatalf("Step: unexpected error: %v", err)
+	}
+	// Each turmite wrote to the cell it stood on, (0,0) and (2,0); the cell
+	// they both moved onto, (1,0), has not been written yet.
+	if owners[0][0] != 0 || owners[2][0] != 1 || owners[1][0] != -1 {
+		t.Errorf("owners (0,0),(2,0),(1,0) = %d,%d,%d, want 0,1,-1", owners[0][0], owners[2][0], owners[1][0])
+	}
+}
@@ -1,16 +1,26 @@
 package main
 
 import (
-	"bufio"
 	"canvas"
 	"flag"
 	"fmt"
+	"image"
 	"image/color"
+	"image/gif"
+	"image/png"
 	"log"
+	"math"
 	"os"
+	"path/filepath"
 	"strings"
+	"terminal"
 )
 
+// frameScale is how many pixels square each field cell renders as in
+// RenderImage, which the GIF and frame-dump output paths use. The -o PNG is
+// drawn by DrawField/DrawFieldTinted with their own scale constant.
+const frameScale = 5
+
 type ColorID int
 type State int
 type Direction int
@@ -39,11 +49,15 @@ type Action struct {
 }
 
 type Turmite struct {
-	rules      map[Signal]Action // rules store the rules stored in the mite file, once read, retain all the time in this object
-	x, y       int               // position
-	currentDir Direction
-	state      State
-	face       string
+	rules       map[Signal]Action // rules store the rules stored in the mite file, once read, retain all the time in this object
+	x, y        int               // position
+	currentDir  Direction
+	state       State
+	face        string
+	id          int         // stable identity within a Colony, used to tint cells by author
+	topology    Topology    // boundary behavior consulted when walking off an edge; defaults to Torus if nil
+	tiles       TileGrid    // devices (mirrors, splitters, teleporters) placed on field cells; nil means none
+	deviceOrder DeviceOrder // whether a mirror is consulted before or after the rule table's own turn
 }
 
 type Field [][]ColorID
@@ -75,6 +89,117 @@ func (f Field) DrawField(filename string) {
 	out.SaveToPNG(filename)
 }
 
+// DrawFieldTinted draws the field like DrawField, but cells that a colony
+// turmite has written to are tinted towards that turmite's accent color
+// (from hsvSpreadColor), so a reader can see at a glance which turmite laid
+// down which trail.
+func (f Field) DrawFieldTinted(filename string, owners OwnerGrid) {
+	const scale = 5
+	n := len(f)
+	out := canvas.CreateNewCanvas(n*scale, n*scale)
+
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			col := f[x][y].ToColor()
+			if owner := owners[x][y]; owner >= 0 {
+				col = blendColor(col, hsvSpreadColor(owner), 0.5)
+			}
+			out.SetFillColor(col)
+			out.ClearRect(x*scale, y*scale, (x+1)*scale, (y+1)*scale)
+		}
+	}
+
+	out.SaveToPNG(filename)
+}
+
+// blendColor linearly interpolates between a and b, where t=0 returns a and
+// t=1 returns b.
+func blendColor(a, b color.Color, t float64) color.Color {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	lerp := func(x, y uint32) uint8 {
+		return uint8((float64(x>>8)*(1-t) + float64(y>>8)*t))
+	}
+	return canvas.MakeColor(lerp(ar, br), lerp(ag, bg), lerp(ab, bb))
+}
+
+// gifPaletteSize is how many distinct ColorID values the GIF/frame-dump
+// palette covers. image/gif frames are paletted, so reusing ColorID.ToColor
+// to build one fixed color.Palette lets every frame encode as indexed
+// pixels instead of truecolor.
+const gifPaletteSize = 64
+
+// gifPalette builds the color.Palette used for paletted GIF frames and
+// frame-dir PNGs, by sampling ColorID.ToColor across the ids a turmite
+// program is likely to use.
+func gifPalette() color.Palette {
+	pal := make(color.Palette, gifPaletteSize)
+	for i := range pal {
+		pal[i] = ColorID(i).ToColor()
+	}
+	return pal
+}
+
+// RenderImage renders the field into a paletted image.Image that any
+// consumer (PNG encoder, GIF frame, etc.) can save, independent of the
+// canvas package DrawField uses. Cell colors that fall outside palette are
+// wrapped via modulo so every field still renders to *some* color.
+func (f Field) RenderImage(palette color.Palette) image.Image {
+	n := len(f)
+	img := image.NewPaletted(image.Rect(0, 0, n*frameScale, n*frameScale), palette)
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			idx := uint8(int(f[x][y]) % len(palette))
+			for dx := 0; dx < frameScale; dx++ {
+				for dy := 0; dy < frameScale; dy++ {
+					img.SetColorIndex(x*frameScale+dx, y*frameScale+dy, idx)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// RenderLive draws the field into buf using half-block characters (▀) so
+// that a single terminal row shows two field rows: the top field row becomes
+// the character's foreground color, the bottom becomes its background. Every
+// turmite's current cell is drawn with reverse video so it stands out while
+// it walks. If mono is true, cells are drawn in the terminal's default color
+// and colors are instead distinguished by ANSI attribute (via
+// terminal.MonoAttrs), for terminals that can't render the truecolor
+// palette; each terminal cell then shows only the top field row's color, and
+// the bottom row of each pair is not drawn.
+func (f Field) RenderLive(buf *terminal.Buffer, colony Colony, mono bool) {
+	n := len(f)
+	for x := 0; x < n; x++ {
+		for row := 0; row*2 < n; row++ {
+			topY := row * 2
+			botY := topY + 1
+
+			var cell terminal.Cell
+			if mono {
+				cell = terminal.Cell{Ch: '█', Attrs: terminal.MonoAttrs(int(f[x][topY]))}
+			} else {
+				cell = terminal.Cell{Ch: '▀', Fg: f[x][topY].ToColor()}
+				if botY < n {
+					cell.Bg = f[x][botY].ToColor()
+				} else {
+					cell.Bg = cell.Fg
+				}
+			}
+
+			for _, t := range colony {
+				if x == t.x && (t.y == topY || t.y == botY) {
+					cell.Attrs |= terminal.AttrReverse
+					break
+				}
+			}
+
+			buf.Set(x, row, cell)
+		}
+	}
+}
+
 // ToRGB returns the red, green, blue values for a given color id.
 func (c ColorID) ToColor() color.Color {
 	colors := [][]uint8{
@@ -85,7 +210,52 @@ func (c ColorID) ToColor() color.Color {
 		{125, 0, 125},
 		{255, 255, 255},
 	}
-	return canvas.MakeColor(colors[c][0], colors[c][1], colors[c][2])
+	if int(c) < len(colors) {
+		return canvas.MakeColor(colors[c][0], colors[c][1], colors[c][2])
+	}
+	// Rule tables imported from ruletablerepository-style formats can declare
+	// far more than 6 colors, so beyond the hard-coded palette we generate a
+	// deterministic spread around the hue wheel using the golden angle: this
+	// keeps adjacent color ids visually distinct no matter how many a table
+	// declares.
+	return hsvSpreadColor(int(c))
+}
+
+// hsvSpreadColor deterministically maps a color id to an RGB color by
+// stepping around the hue wheel by the golden angle, which spreads any
+// number of ids across visually distinct hues without clustering.
+func hsvSpreadColor(i int) color.Color {
+	const goldenRatioConjugate = 0.6180339887498949
+	hue := math.Mod(float64(i)*goldenRatioConjugate, 1.0)
+	r, g, b := hsvToRGB(hue, 0.65, 0.95)
+	return canvas.MakeColor(r, g, b)
+}
+
+// hsvToRGB converts a color expressed in hue/saturation/value (each in
+// [0,1]) to 8-bit red/green/blue components.
+func hsvToRGB(h, s, v float64) (uint8, uint8, uint8) {
+	i := math.Floor(h * 6)
+	f := h*6 - i
+	p := v * (1 - s)
+	q := v * (1 - f*s)
+	t := v * (1 - (1-f)*s)
+
+	var r, g, b float64
+	switch int(i) % 6 {
+	case 0:
+		r, g, b = v, t, p
+	case 1:
+		r, g, b = q, v, p
+	case 2:
+		r, g, b = p, v, t
+	case 3:
+		r, g, b = p, q, v
+	case 4:
+		r, g, b = t, p, v
+	case 5:
+		r, g, b = v, p, q
+	}
+	return uint8(r * 255), uint8(g * 255), uint8(b * 255)
 }
 
 // DirFromString returns a direction constant given an English string.
@@ -109,211 +279,187 @@ func PositiveMod(n, m int) int {
 	return ((n % m) + m) % m
 }
 
-// Left returns the direction turing 90 degrees left of d.
-func (d Direction) Left() Direction {
-	return Direction(PositiveMod(int(d)-1, 4))
-}
-
-// Right returns the direction turning 90 degrees right of d.
-func (d Direction) Right() Direction {
-	return Direction(PositiveMod(int(d)+1, 4))
-}
-
-// ReadTurmite reads a file that specifies the turmite rules. The file should
-// have lines of the format:
-//
-//  state color -> state color direction
-//
-// where state is a lowercase letter a-z; color is an integer;  direction is a
-// direction understood by DirFromString. The returned Turmite will be
-// positioned at the center of the field and facing north (aka ForwardDir).
-func ReadTurmite(filename string, size int) (*Turmite, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
+// programFormat decides whether a turmite program file should be parsed as
+// the native "rule" format or a Golly-style "table". An explicit -format
+// flag always wins; otherwise the format is sniffed from the file's
+// extension, with ".table" and ".rul" recognized as rule tables.
+func programFormat(filename, explicit string) string {
+	if explicit != "" {
+		return explicit
 	}
-	defer file.Close()
-
-	// the initial state, center, half x and half y, facing north
-	tur := Turmite{
-		x:          size / 2,
-		y:          size / 2,
-		currentDir: NorthDir,
-		state:      0,
-		rules:      make(map[Signal]Action),
-		face:       "North",
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".table", ".rul":
+		return "table"
+	default:
+		return "rule"
 	}
+}
 
-	scanner := bufio.NewScanner(file)
-	for lineno := 1; scanner.Scan(); lineno++ {
-		line := strings.TrimSpace(scanner.Text())
-		if len(line) == 0 || line[0] == '#' {
-			continue
-		}
+// stringSliceFlag is a flag.Value that accumulates every occurrence of a
+// repeated flag, so e.g. -prog can be passed multiple times to build up a
+// Colony from several program files.
+type stringSliceFlag []string
 
-		var color_in, color_out ColorID
-		var dirString string
-		var state_in_char, state_out_char rune
-
-		// scan the argument string, storing successive space-separated values into successive arguments as determined by the format
-		n, err := fmt.Sscanf(line, "%c %d -> %c %d %s",
-			&state_in_char,
-			&color_in,
-			&state_out_char,
-			&color_out,
-			&dirString)
-		if err != nil || n != 5 {
-			return nil, fmt.Errorf("Badly formatted line: %d", lineno)
-		}
-		state_in := State(state_in_char - 'a')
-		state_out := State(state_out_char - 'a')
-		dir, err := DirFromString(dirString)
-		if err != nil {
-			return nil, err
-		}
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
 
-		// read the rules from mite file and attach it to the rules in this mite object
-		tur.rules[Signal{state: state_in, color: color_in}] = Action{
-			state: state_out,
-			color: color_out,
-			turn:  dir,
-		}
-	}
-	fmt.Printf("Read turmite with %d rules\n", len(tur.rules))
-	return &tur, nil
+func (s *stringSliceFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
 }
 
-// Step moves the turmite one step using the given field. Return an error if the
-// turmite gets stuck with no rule to apply.
-func (t *Turmite) Step(field Field) error {
-	// field is the input,which would store the board
+func main() {
+	var programs stringSliceFlag
+	var pngfile, format, collisionFlag, topologyFlag, gifFile, framesDir string
+	var fieldSize, iters, frameEvery int
+	var live, tint, mono bool
 
-	// sense the color and find the suitable rules ==> obtain the signal based on the current location
-	currState := t.state
-	currColor := field[t.x][t.y]
+	flag.Var(&programs, "prog", "File containing a turmite program; repeat to run a colony of several turmites")
+	flag.IntVar(&fieldSize, "s", 100, "Size of the field")
+	flag.IntVar(&iters, "steps", 100000, "Number of steps")
+	flag.StringVar(&pngfile, "o", "output.png", "Filename to draw output")
+	flag.BoolVar(&live, "live", false, "Render each step live to the terminal instead of only a final PNG")
+	flag.BoolVar(&mono, "mono", false, "With -live, style cells by ANSI attribute instead of truecolor, for monochrome terminals")
+	flag.StringVar(&format, "format", "", "Program file format: \"rule\" or \"table\" (default: sniffed from the file extension)")
+	flag.StringVar(&collisionFlag, "collision", "ignore", "Collision mode when turmites share a cell: ignore, block, annihilate, merge")
+	flag.BoolVar(&tint, "tint", false, "Tint the output PNG by which turmite last wrote each cell")
+	flag.StringVar(&topologyFlag, "topology", "torus", "Field boundary behavior: torus, reflect, bounded")
+	flag.StringVar(&gifFile, "gif", "", "Filename to write an animated GIF capturing the field every -frame-every steps")
+	flag.StringVar(&framesDir, "frames-dir", "", "Directory to dump numbered PNG frames into, every -frame-every steps")
+	flag.IntVar(&frameEvery, "frame-every", 100, "Capture a frame every N steps for -gif/-frames-dir")
+	var deviceOrderFlag string
+	flag.StringVar(&deviceOrderFlag, "device-order", "before", "When a mirror tile applies relative to the rule table's turn: before, after")
+	flag.Parse()
 
-	currSignal := Signal{
-		state: currState,
-		color: currColor,
+	if len(programs) == 0 {
+		programs = append(programs, "example1.mite")
 	}
 
-	// find the rules based on the signal
-	nextAction := t.rules[currSignal]
-
-	// set its state to a new value in a...z.
-	t.state = nextAction.state
+	collisionMode, err := CollisionModeFromString(collisionFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// change the color of the square that it is on to some color
-	field[t.x][t.y] = nextAction.color
+	topology, err := TopologyFromString(topologyFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// Turn degrees relative to the direction it is facing
-	degrees := nextAction.turn
+	deviceOrder, err := DeviceOrderFromString(deviceOrderFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// obtain the existing direction
-	currFace := t.face
+	// Tiles are a property of the shared field, so they're read once from
+	// the first program file and apply to every turmite in the colony.
+	tiles, err := ReadTiles(programs[0], fieldSize)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	switch currFace {
-	case "North":
-		t.NorthMove(degrees)
-	case "South":
-		t.SouthMove(degrees)
-	case "East":
-		t.EastMove(degrees)
-	case "West":
-		t.WestMove(degrees)
+	var colony Colony
+	for i, program := range programs {
+		var mite *Turmite
+		var err error
+		if programFormat(program, format) == "table" {
+			mite, err = ReadTurmiteTable(program, fieldSize)
+		} else {
+			mite, err = ReadTurmite(program, fieldSize)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		mite.id = i
+		mite.topology = topology
+		mite.tiles = tiles
+		mite.deviceOrder = deviceOrder
+		colony = append(colony, mite)
 	}
-	//t.currentDir = nextAction.turn
-
-	// Walk one step in the direction it is facing
-	if t.face == "North" {
-		t.y = t.y - 1
-	} else if t.face == "East" {
-		t.x = t.x + 1
-	} else if t.face == "South" {
-		t.y = t.y + 1
-	} else if t.face == "West" {
-		t.x = t.x - 1
-	} else {
-		fmt.Println("Error occurred")
+
+	field := NewField(fieldSize)
+	owners := NewOwnerGrid(fieldSize)
+
+	var buf *terminal.Buffer
+	if live {
+		buf = terminal.NewBuffer(fieldSize, (fieldSize+1)/2)
 	}
-	return nil
-}
 
-func (t *Turmite) NorthMove(degrees Direction) {
-	if degrees == 0 {
-		t.face = "North"
-	} else if degrees == 1 {
-		t.face = "East"
-	} else if degrees == 2 {
-		t.face = "South"
-	} else {
-		t.face = "West"
+	capturing := gifFile != "" || framesDir != ""
+	var palette color.Palette
+	var anim gif.GIF
+	if capturing {
+		if frameEvery <= 0 {
+			log.Fatalf("-frame-every must be positive, got %d", frameEvery)
+		}
+		palette = gifPalette()
+		if framesDir != "" {
+			if err := os.MkdirAll(framesDir, 0755); err != nil {
+				log.Fatal(err)
+			}
+		}
 	}
 
-}
-func (t *Turmite) SouthMove(degrees Direction) {
-	if degrees == 0 {
-		t.face = "South"
-	} else if degrees == 1 {
-		t.face = "West"
-	} else if degrees == 2 {
-		t.face = "North"
-	} else {
-		t.face = "East"
+	//count :=0
+	for i := 0; i < iters && len(colony) > 0; i++ {
+		colony, err = colony.Step(field, collisionMode, owners)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if live {
+			field.RenderLive(buf, colony, mono)
+			buf.Flush(os.Stdout)
+		}
+		if capturing && i%frameEvery == 0 {
+			frame := field.RenderImage(palette).(*image.Paletted)
+			if gifFile != "" {
+				anim.Image = append(anim.Image, frame)
+				anim.Delay = append(anim.Delay, gifFrameDelay)
+			}
+			if framesDir != "" {
+				savePNG(filepath.Join(framesDir, fmt.Sprintf("frame%06d.png", i)), frame)
+			}
+		}
+		//count++
+		//fmt.Println(count)
 	}
-}
-func (t *Turmite) EastMove(degrees Direction) {
-
-	if degrees == 0 {
-		t.face = "East"
-	} else if degrees == 1 {
-		t.face = "South"
-	} else if degrees == 2 {
-		t.face = "West"
-	} else {
-		t.face = "North"
+
+	if gifFile != "" {
+		saveGIF(gifFile, &anim)
 	}
-}
-func (t *Turmite) WestMove(degrees Direction) {
-
-	if degrees == 0 {
-		t.face = "West"
-	} else if degrees == 1 {
-		t.face = "North"
-	} else if degrees == 2 {
-		t.face = "East"
+
+	if tint {
+		field.DrawFieldTinted(pngfile, owners)
 	} else {
-		t.face = "South"
+		field.DrawField(pngfile)
 	}
 }
 
-func main() {
-	var program, pngfile string
-	var fieldSize, iters int
+// gifFrameDelay is the per-frame delay, in 100ths of a second, for captured
+// GIF frames.
+const gifFrameDelay = 5
 
-	flag.StringVar(&program, "prog", "example1.mite", "File containing the turmite program")
-	flag.IntVar(&fieldSize, "s", 100, "Size of the field")
-	flag.IntVar(&iters, "steps", 100000, "Number of steps")
-	flag.StringVar(&pngfile, "o", "output.png", "Filename to draw output")
-	flag.Parse()
-
-	if program == "" {
-		log.Fatal("Must supply a program file with -prog.")
+// savePNG writes img to filename as a PNG, fataling on error like the rest
+// of this program's I/O.
+func savePNG(filename string, img image.Image) {
+	f, err := os.Create(filename)
+	if err != nil {
+		log.Fatal(err)
 	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		log.Fatal(err)
+	}
+}
 
-	mite, err := ReadTurmite(program, fieldSize)
+// saveGIF writes anim to filename as a looping animated GIF.
+func saveGIF(filename string, anim *gif.GIF) {
+	f, err := os.Create(filename)
 	if err != nil {
 		log.Fatal(err)
 	}
-	field := NewField(fieldSize)
-	//count :=0
-	for i := 0; i < iters; i++ {
-		err := mite.Step(field)
-		if err != nil {
-			log.Fatal(err)
-		}
-		//count++
-		//fmt.Println(count)
+	defer f.Close()
+	if err := gif.EncodeAll(f, anim); err != nil {
+		log.Fatal(err)
 	}
-	field.DrawField(pngfile)
 }
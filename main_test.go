@@ -0,0 +1,36 @@
+package main
+
+import (
+	"image"
+	"testing"
+)
+
+func TestGifPaletteSamplesToColor(t *testing.T) {
+	pal := gifPalette()
+	if len(pal) != gifPaletteSize {
+		t.Fatalf("gifPalette: got %d entries, want %d", len(pal), gifPaletteSize)
+	}
+
+	for _, id := range []int{0, 5, 10} {
+		want := ColorID(id).ToColor()
+		wr, wg, wb, _ := want.RGBA()
+		gr, gg, gb, _ := pal[id].RGBA()
+		if gr != wr || gg != wg || gb != wb {
+			t.Errorf("gifPalette()[%d] = %v, want %v (ColorID(%d).ToColor())", id, pal[id], want, id)
+		}
+	}
+}
+
+func TestRenderImageWrapsPaletteIndex(t *testing.T) {
+	field := NewField(1)
+	field[0][0] = ColorID(gifPaletteSize + 3)
+	palette := gifPalette()
+
+	img := field.RenderImage(palette).(*image.Paletted)
+
+	wantIdx := uint8((gifPaletteSize + 3) % len(palette))
+	gotIdx := img.ColorIndexAt(0, 0)
+	if gotIdx != wantIdx {
+		t.Errorf("RenderImage: color index = %d, want %d (wrapped via modulo)", gotIdx, wantIdx)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Left returns the direction turing 90 degrees left of d.
+func (d Direction) Left() Direction {
+	return Direction(PositiveMod(int(d)-1, 4))
+}
+
+// Right returns the direction turning 90 degrees right of d.
+func (d Direction) Right() Direction {
+	return Direction(PositiveMod(int(d)+1, 4))
+}
+
+// ReadTurmite reads a file that specifies the turmite rules. The file should
+// have lines of the format:
+//
+//	state color -> state color direction
+//
+// where state is a lowercase letter a-z; color is an integer;  direction is a
+// direction understood by DirFromString. The returned Turmite will be
+// positioned at the center of the field and facing north (aka ForwardDir).
+func ReadTurmite(filename string, size int) (*Turmite, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// the initial state, center, half x and half y, facing north
+	tur := Turmite{
+		x:          size / 2,
+		y:          size / 2,
+		currentDir: NorthDir,
+		state:      0,
+		rules:      make(map[Signal]Action),
+		face:       "North",
+	}
+
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if strings.EqualFold(line, "[tiles]") {
+			// A [tiles] section always comes after the rules; ReadTiles
+			// parses it separately.
+			break
+		}
+
+		var color_in, color_out ColorID
+		var dirString string
+		var state_in_char, state_out_char rune
+
+		// scan the argument string, storing successive space-separated values into successive arguments as determined by the format
+		n, err := fmt.Sscanf(line, "%c %d -> %c %d %s",
+			&state_in_char,
+			&color_in,
+			&state_out_char,
+			&color_out,
+			&dirString)
+		if err != nil || n != 5 {
+			return nil, fmt.Errorf("Badly formatted line: %d", lineno)
+		}
+		state_in := State(state_in_char - 'a')
+		state_out := State(state_out_char - 'a')
+		dir, err := DirFromString(dirString)
+		if err != nil {
+			return nil, err
+		}
+
+		// read the rules from mite file and attach it to the rules in this mite object
+		tur.rules[Signal{state: state_in, color: color_in}] = Action{
+			state: state_out,
+			color: color_out,
+			turn:  dir,
+		}
+	}
+	fmt.Printf("Read turmite with %d rules\n", len(tur.rules))
+	return &tur, nil
+}
+
+// turnFromTableCode translates the bitmask turn codes used by
+// ruletablerepository-style rule tables (1=no turn, 2=right, 4=u-turn,
+// 8=left) into this package's Direction semantics.
+func turnFromTableCode(code int) (Direction, error) {
+	switch code {
+	case 1:
+		return ForwardDir, nil
+	case 2:
+		return RightDir, nil
+	case 4:
+		return BackwardDir, nil
+	case 8:
+		return LeftDir, nil
+	default:
+		return 0, fmt.Errorf("unknown turn code: %d", code)
+	}
+}
+
+// ReadTurmiteTable reads a Golly-style N-state/M-color turmite rule table, as
+// circulated in the ruletablerepository collection. The file should have a
+// header declaring the table dimensions:
+//
+//	n_states=2
+//	n_colors=3
+//
+// followed by one triple per line, `new_color turn new_state`, giving the
+// transition for each (state, color) pair in order, indexed by
+// state*n_colors+color. turn is a bitmask: 1=no turn, 2=right, 4=u-turn,
+// 8=left. The returned Turmite will be positioned at the center of the
+// field and facing north (aka ForwardDir).
+func ReadTurmiteTable(filename string, size int) (*Turmite, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tur := Turmite{
+		x:          size / 2,
+		y:          size / 2,
+		currentDir: NorthDir,
+		state:      0,
+		rules:      make(map[Signal]Action),
+		face:       "North",
+	}
+
+	nStates, nColors := -1, -1
+	nextState, nextColor := 0, 0
+
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if strings.EqualFold(line, "[tiles]") {
+			// A [tiles] section always comes after the rule triples;
+			// ReadTiles parses it separately.
+			break
+		}
+
+		if strings.HasPrefix(line, "n_states=") {
+			nStates, err = parseTableInt(line, "n_states=")
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "n_colors=") {
+			nColors, err = parseTableInt(line, "n_colors=")
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if nStates < 0 || nColors < 0 {
+			return nil, fmt.Errorf("line %d: rule triple before n_states/n_colors header", lineno)
+		}
+
+		var newColor, turnCode, newState int
+		n, err := fmt.Sscanf(line, "%d %d %d", &newColor, &turnCode, &newState)
+		if err != nil || n != 3 {
+			return nil, fmt.Errorf("line %d: badly formatted rule triple", lineno)
+		}
+		if nextState >= nStates {
+			return nil, fmt.Errorf("line %d: more rule triples than n_states*n_colors", lineno)
+		}
+
+		turn, err := turnFromTableCode(turnCode)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineno, err)
+		}
+
+		tur.rules[Signal{state: State(nextState), color: ColorID(nextColor)}] = Action{
+			state: State(newState),
+			color: ColorID(newColor),
+			turn:  turn,
+		}
+
+		nextColor++
+		if nextColor >= nColors {
+			nextColor = 0
+			nextState++
+		}
+	}
+
+	fmt.Printf("Read turmite table with %d rules\n", len(tur.rules))
+	return &tur, nil
+}
+
+// parseTableInt extracts the integer value following prefix in line, e.g.
+// parseTableInt("n_states=3", "n_states=") returns 3.
+func parseTableInt(line, prefix string) (int, error) {
+	var v int
+	_, err := fmt.Sscanf(strings.TrimPrefix(line, prefix), "%d", &v)
+	if err != nil {
+		return 0, fmt.Errorf("badly formatted %s%v", prefix, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTurnFromTableCode(t *testing.T) {
+	cases := []struct {
+		code int
+		want Direction
+	}{
+		{1, ForwardDir},
+		{2, RightDir},
+		{4, BackwardDir},
+		{8, LeftDir},
+	}
+	for _, c := range cases {
+		got, err := turnFromTableCode(c.code)
+		if err != nil {
+			t.Errorf("turnFromTableCode(%d): unexpected error: %v", c.code, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("turnFromTableCode(%d) = %v, want %v", c.code, got, c.want)
+		}
+	}
+
+	if _, err := turnFromTableCode(3); err == nil {
+		t.Error("turnFromTableCode(3): expected error for unknown turn code, got nil")
+	}
+}
+
+func TestParseTableInt(t *testing.T) {
+	got, err := parseTableInt("n_states=3", "n_states=")
+	if err != nil {
+		t.Fatalf("parseTableInt: unexpected error: %v", err)
+	}
+	if got != 3 {
+		t.Errorf("parseTableInt(\"n_states=3\") = %d, want 3", got)
+	}
+
+	if _, err := parseTableInt("n_states=three", "n_states="); err == nil {
+		t.Error("parseTableInt(\"n_states=three\"): expected error, got nil")
+	}
+}
+
+func TestReadTurmiteTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.table")
+	contents := "n_states=2\nn_colors=2\n1 2 1\n0 2 0\n0 8 0\n1 8 1\n"
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write test table: %v", err)
+	}
+
+	mite, err := ReadTurmiteTable(path, 10)
+	if err != nil {
+		t.Fatalf("ReadTurmiteTable: unexpected error: %v", err)
+	}
+	if len(mite.rules) != 4 {
+		t.Fatalf("ReadTurmiteTable: got %d rules, want 4", len(mite.rules))
+	}
+
+	action, ok := mite.rules[Signal{state: 0, color: 0}]
+	if !ok {
+		t.Fatal("ReadTurmiteTable: missing rule for (state=0, color=0)")
+	}
+	if action.state != 1 || action.color != 1 || action.turn != RightDir {
+		t.Errorf("rule for (0,0) = %+v, want state=1 color=1 turn=RightDir", action)
+	}
+
+	if mite.x != 5 || mite.y != 5 {
+		t.Errorf("ReadTurmiteTable: starting position = (%d,%d), want (5,5)", mite.x, mite.y)
+	}
+}
+
+func TestReadTurmiteTableRejectsTripleBeforeHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.table")
+	contents := "1 2 1\nn_states=1\nn_colors=1\n"
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write test table: %v", err)
+	}
+
+	if _, err := ReadTurmiteTable(path, 10); err == nil {
+		t.Error("ReadTurmiteTable: expected error for rule triple before header, got nil")
+	}
+}
+
+func TestReadTurmiteTableLoadsShippedLangtonTable(t *testing.T) {
+	mite, err := ReadTurmiteTable("tables/langton.table", 10)
+	if err != nil {
+		t.Fatalf("ReadTurmiteTable(tables/langton.table): unexpected error: %v", err)
+	}
+	if len(mite.rules) != 2 {
+		t.Fatalf("ReadTurmiteTable(tables/langton.table): got %d rules, want 2", len(mite.rules))
+	}
+
+	onWhite, ok := mite.rules[Signal{state: 0, color: 0}]
+	if !ok {
+		t.Fatal("tables/langton.table: missing rule for (state=0, color=0)")
+	}
+	if onWhite.color != 1 || onWhite.turn != RightDir {
+		t.Errorf("tables/langton.table: rule for (0,0) = %+v, want color=1 turn=RightDir", onWhite)
+	}
+
+	onBlack, ok := mite.rules[Signal{state: 0, color: 1}]
+	if !ok {
+		t.Fatal("tables/langton.table: missing rule for (state=0, color=1)")
+	}
+	if onBlack.color != 0 || onBlack.turn != LeftDir {
+		t.Errorf("tables/langton.table: rule for (0,1) = %+v, want color=0 turn=LeftDir", onBlack)
+	}
+}
+
+func TestHsvSpreadColorDistinctIDs(t *testing.T) {
+	a := hsvSpreadColor(10)
+	b := hsvSpreadColor(11)
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	if ar == br && ag == bg && ab == bb {
+		t.Error("hsvSpreadColor(10) and hsvSpreadColor(11) produced the same color, want distinct colors")
+	}
+}
+
+func TestToColorBeyondPalette(t *testing.T) {
+	// ColorID 6 is past the 6-entry hard-coded palette, so it should fall
+	// back to hsvSpreadColor rather than panic or alias color 0.
+	got := ColorID(6).ToColor()
+	want := hsvSpreadColor(6)
+	gr, gg, gb, _ := got.RGBA()
+	wr, wg, wb, _ := want.RGBA()
+	if gr != wr || gg != wg || gb != wb {
+		t.Errorf("ColorID(6).ToColor() = %v, want %v", got, want)
+	}
+}
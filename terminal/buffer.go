@@ -0,0 +1,165 @@
+// Package terminal implements a small buffered terminal renderer modeled on
+// the double-buffering approach used by the ginger project: callers fill in
+// a grid of cells describing what the screen should look like, and Flush
+// diffs that grid against the previously flushed one so only the cells that
+// actually changed are re-drawn.
+package terminal
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+)
+
+// Attr is a bitmask of ANSI text attributes that can be layered onto a Cell
+// in addition to its foreground/background color.
+type Attr int
+
+const (
+	AttrNone Attr = 0
+	AttrBold Attr = 1 << iota
+	AttrDim
+	AttrUnderline
+	AttrReverse
+)
+
+// Cell is a single character position on screen: the rune to draw, its
+// foreground and background color, and any ANSI attributes.
+type Cell struct {
+	Ch    rune
+	Fg    color.Color
+	Bg    color.Color
+	Attrs Attr
+}
+
+// emptyCell is what a freshly created Buffer is filled with.
+var emptyCell = Cell{Ch: ' '}
+
+// Buffer owns a 2D grid of Cells representing the next frame to draw, plus
+// the previously flushed frame so Flush can emit only what changed.
+type Buffer struct {
+	w, h  int
+	cells []Cell
+	prev  []Cell
+	first bool
+}
+
+// NewBuffer creates a Buffer of the given width and height, in terminal
+// columns and rows.
+func NewBuffer(w, h int) *Buffer {
+	b := &Buffer{
+		w:     w,
+		h:     h,
+		cells: make([]Cell, w*h),
+		prev:  make([]Cell, w*h),
+		first: true,
+	}
+	for i := range b.cells {
+		b.cells[i] = emptyCell
+	}
+	return b
+}
+
+// index returns the flat index of the cell at (x,y), or -1 if out of range.
+func (b *Buffer) index(x, y int) int {
+	if x < 0 || y < 0 || x >= b.w || y >= b.h {
+		return -1
+	}
+	return y*b.w + x
+}
+
+// Set stores c as the cell to draw at (x,y) on the next Flush. Out-of-range
+// coordinates are silently ignored.
+func (b *Buffer) Set(x, y int, c Cell) {
+	if i := b.index(x, y); i >= 0 {
+		b.cells[i] = c
+	}
+}
+
+// Clear resets every cell back to a blank space.
+func (b *Buffer) Clear() {
+	for i := range b.cells {
+		b.cells[i] = emptyCell
+	}
+}
+
+// monoAttrs is the fixed cycle of attribute combinations MonoAttrs draws
+// from. It's ordered from least to most visually distinct so low ids stay
+// legible and only less common ids reach for combinations.
+var monoAttrs = []Attr{
+	AttrNone,
+	AttrBold,
+	AttrDim,
+	AttrUnderline,
+	AttrReverse,
+	AttrBold | AttrUnderline,
+	AttrDim | AttrUnderline,
+	AttrBold | AttrReverse,
+}
+
+// MonoAttrs deterministically maps an arbitrary id (e.g. a rule table's
+// color id) to one of a fixed set of ANSI attribute combinations, by cycling
+// through monoAttrs. This lets rule authors distinguish cells by style
+// (bold, dim, underline, reverse) on monochrome terminals that can't render
+// the truecolor palette RenderLive otherwise uses.
+func MonoAttrs(id int) Attr {
+	return monoAttrs[((id%len(monoAttrs))+len(monoAttrs))%len(monoAttrs)]
+}
+
+// ansiAttrs returns the ANSI SGR codes for the attributes set in a.
+func ansiAttrs(a Attr) []int {
+	var codes []int
+	if a&AttrBold != 0 {
+		codes = append(codes, 1)
+	}
+	if a&AttrDim != 0 {
+		codes = append(codes, 2)
+	}
+	if a&AttrUnderline != 0 {
+		codes = append(codes, 4)
+	}
+	if a&AttrReverse != 0 {
+		codes = append(codes, 7)
+	}
+	return codes
+}
+
+// writeCell writes the ANSI escape sequence and rune for a single cell to w.
+func writeCell(w io.Writer, c Cell) {
+	fmt.Fprintf(w, "\x1b[0")
+	for _, code := range ansiAttrs(c.Attrs) {
+		fmt.Fprintf(w, ";%d", code)
+	}
+	if c.Fg != nil {
+		fr, fg, fb, _ := c.Fg.RGBA()
+		fmt.Fprintf(w, ";38;2;%d;%d;%d", fr>>8, fg>>8, fb>>8)
+	}
+	if c.Bg != nil {
+		br, bg, bb, _ := c.Bg.RGBA()
+		fmt.Fprintf(w, ";48;2;%d;%d;%d", br>>8, bg>>8, bb>>8)
+	}
+	fmt.Fprintf(w, "m")
+	fmt.Fprintf(w, "%c", c.Ch)
+}
+
+// Flush writes the cells that differ from the previously flushed frame to w
+// as ANSI cursor-positioning and SGR escape sequences, then resets attrs.
+// The first call always draws every cell, since there is no prior frame to
+// diff against.
+func (b *Buffer) Flush(w io.Writer) error {
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			i := b.index(x, y)
+			c := b.cells[i]
+			if !b.first && c == b.prev[i] {
+				continue
+			}
+			fmt.Fprintf(w, "\x1b[%d;%dH", y+1, x+1)
+			writeCell(w, c)
+		}
+	}
+	fmt.Fprint(w, "\x1b[0m")
+	copy(b.prev, b.cells)
+	b.first = false
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type DeviceKind int
+
+const (
+	DeviceNone DeviceKind = iota
+	// DeviceMirrorSlash reflects a turmite's heading like a '/' mirror:
+	// North<->East, South<->West.
+	DeviceMirrorSlash
+	// DeviceMirrorBackslash reflects a turmite's heading like a '\' mirror:
+	// North<->West, South<->East.
+	DeviceMirrorBackslash
+	// DeviceSplitterHorizontal ('-') splits a turmite moving North or South
+	// into two turmites moving East and West.
+	DeviceSplitterHorizontal
+	// DeviceSplitterVertical ('|') splits a turmite moving East or West
+	// into two turmites moving North and South.
+	DeviceSplitterVertical
+	// DeviceTeleporter jumps a turmite arriving on this cell to the paired
+	// cell at (TeleportX, TeleportY), preserving its heading.
+	DeviceTeleporter
+)
+
+// Device is a single special tile placed on a field cell by a [tiles]
+// section.
+type Device struct {
+	Kind                 DeviceKind
+	TeleportX, TeleportY int // target cell, only meaningful for DeviceTeleporter
+}
+
+// Position identifies a field cell, used as a TileGrid map key.
+type Position struct {
+	X, Y int
+}
+
+// TileGrid maps field cells to the Device placed on them. A nil TileGrid
+// behaves like an empty one: every lookup simply reports no device.
+type TileGrid map[Position]Device
+
+// NewTileGrid creates an empty TileGrid.
+func NewTileGrid() TileGrid {
+	return make(TileGrid)
+}
+
+// DeviceOrder controls whether a mirror device is consulted before or after
+// the rule table's own turn is applied for the tick a turmite spends on it.
+type DeviceOrder int
+
+const (
+	// DeviceBeforeRule reflects the turmite's incoming heading first, so the
+	// rule table's turn is applied relative to the reflected heading.
+	DeviceBeforeRule DeviceOrder = iota
+	// DeviceAfterRule applies the rule table's turn first, then reflects the
+	// resulting heading, so the device has the final say.
+	DeviceAfterRule
+)
+
+// DeviceOrderFromString returns a DeviceOrder constant given an English
+// string.
+func DeviceOrderFromString(s string) (DeviceOrder, error) {
+	switch strings.ToLower(s) {
+	case "before":
+		return DeviceBeforeRule, nil
+	case "after":
+		return DeviceAfterRule, nil
+	default:
+		return 0, fmt.Errorf("unknown device order: %s", s)
+	}
+}
+
+// reflectFace applies a mirror device to face, leaving non-mirror devices
+// (and unrecognized combinations) unchanged.
+func reflectFace(face string, kind DeviceKind) string {
+	switch kind {
+	case DeviceMirrorSlash:
+		switch face {
+		case "North":
+			return "East"
+		case "East":
+			return "North"
+		case "South":
+			return "West"
+		case "West":
+			return "South"
+		}
+	case DeviceMirrorBackslash:
+		switch face {
+		case "North":
+			return "West"
+		case "West":
+			return "North"
+		case "South":
+			return "East"
+		case "East":
+			return "South"
+		}
+	}
+	return face
+}
+
+// splitAxes returns the two perpendicular headings a beam splitter sends a
+// turmite off in, given the heading it arrived with. It returns two empty
+// strings if kind isn't a splitter or face passes straight through it.
+func splitAxes(kind DeviceKind, face string) (string, string) {
+	switch kind {
+	case DeviceSplitterHorizontal:
+		if face == "North" || face == "South" {
+			return "East", "West"
+		}
+	case DeviceSplitterVertical:
+		if face == "East" || face == "West" {
+			return "North", "South"
+		}
+	}
+	return "", ""
+}
+
+// ReadTiles reads the optional [tiles] section of a .mite program file,
+// which places mirrors, splitters, and teleporters onto specific field
+// cells:
+//
+//	[tiles]
+//	x y /                mirror reflecting like /
+//	x y \                mirror reflecting like \
+//	x y |                vertical beam splitter
+//	x y -                horizontal beam splitter
+//	x y teleport tx ty   teleporter paired with the cell at (tx,ty)
+//
+// size is the field's side length; every coordinate (including a
+// teleporter's target) must fall within [0,size) or ReadTiles returns an
+// error, since an out-of-range teleport target would otherwise panic the
+// next time a turmite landed on it.
+//
+// Returns an empty TileGrid if the file has no [tiles] section.
+func ReadTiles(filename string, size int) (TileGrid, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tiles := NewTileGrid()
+	inTiles := false
+
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if !inTiles {
+			if strings.EqualFold(line, "[tiles]") {
+				inTiles = true
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: badly formatted tile", lineno)
+		}
+		x, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineno, err)
+		}
+		y, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineno, err)
+		}
+		if x < 0 || x >= size || y < 0 || y >= size {
+			return nil, fmt.Errorf("line %d: tile (%d,%d) is outside the %dx%d field", lineno, x, y, size, size)
+		}
+
+		switch fields[2] {
+		case "/":
+			tiles[Position{x, y}] = Device{Kind: DeviceMirrorSlash}
+		case "\\":
+			tiles[Position{x, y}] = Device{Kind: DeviceMirrorBackslash}
+		case "|":
+			tiles[Position{x, y}] = Device{Kind: DeviceSplitterVertical}
+		case "-":
+			tiles[Position{x, y}] = Device{Kind: DeviceSplitterHorizontal}
+		case "teleport":
+			if len(fields) != 5 {
+				return nil, fmt.Errorf("line %d: teleport tile needs a target x y", lineno)
+			}
+			tx, err := strconv.Atoi(fields[3])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineno, err)
+			}
+			ty, err := strconv.Atoi(fields[4])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineno, err)
+			}
+			if tx < 0 || tx >= size || ty < 0 || ty >= size {
+				return nil, fmt.Errorf("line %d: teleport target (%d,%d) is outside the %dx%d field", lineno, tx, ty, size, size)
+			}
+			tiles[Position{x, y}] = Device{Kind: DeviceTeleporter, TeleportX: tx, TeleportY: ty}
+		default:
+			return nil, fmt.Errorf("line %d: unknown tile type %q", lineno, fields[2])
+		}
+	}
+	return tiles, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTileFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.mite")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write tile file: %v", err)
+	}
+	return path
+}
+
+func TestReadTilesParsesEachDeviceKind(t *testing.T) {
+	path := writeTileFile(t, "[tiles]\n1 1 /\n2 2 \\\n3 3 |\n4 4 -\n5 5 teleport 6 6\n")
+
+	tiles, err := ReadTiles(path, 10)
+	if err != nil {
+		t.Fatalf("ReadTiles: unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		pos  Position
+		want Device
+	}{
+		{Position{1, 1}, Device{Kind: DeviceMirrorSlash}},
+		{Position{2, 2}, Device{Kind: DeviceMirrorBackslash}},
+		{Position{3, 3}, Device{Kind: DeviceSplitterVertical}},
+		{Position{4, 4}, Device{Kind: DeviceSplitterHorizontal}},
+		{Position{5, 5}, Device{Kind: DeviceTeleporter, TeleportX: 6, TeleportY: 6}},
+	}
+	for _, c := range cases {
+		got, ok := tiles[c.pos]
+		if !ok {
+			t.Errorf("ReadTiles: missing tile at %v", c.pos)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("ReadTiles: tile at %v = %+v, want %+v", c.pos, got, c.want)
+		}
+	}
+}
+
+func TestReadTilesRejectsOutOfRangeTile(t *testing.T) {
+	path := writeTileFile(t, "[tiles]\n20 20 /\n")
+	if _, err := ReadTiles(path, 10); err == nil {
+		t.Error("ReadTiles: expected error for a tile outside the field, got nil")
+	}
+}
+
+func TestReadTilesRejectsOutOfRangeTeleportTarget(t *testing.T) {
+	path := writeTileFile(t, "[tiles]\n1 1 teleport 99 99\n")
+	if _, err := ReadTiles(path, 10); err == nil {
+		t.Error("ReadTiles: expected error for a teleport target outside the field, got nil")
+	}
+}
+
+func TestReflectFaceMirrors(t *testing.T) {
+	cases := []struct {
+		face string
+		kind DeviceKind
+		want string
+	}{
+		{"North", DeviceMirrorSlash, "East"},
+		{"East", DeviceMirrorSlash, "North"},
+		{"North", DeviceMirrorBackslash, "West"},
+		{"West", DeviceMirrorBackslash, "North"},
+		{"North", DeviceNone, "North"},
+	}
+	for _, c := range cases {
+		got := reflectFace(c.face, c.kind)
+		if got != c.want {
+			t.Errorf("reflectFace(%q, %v) = %q, want %q", c.face, c.kind, got, c.want)
+		}
+	}
+}
+
+func TestSplitAxes(t *testing.T) {
+	a1, a2 := splitAxes(DeviceSplitterHorizontal, "North")
+	if a1 != "East" || a2 != "West" {
+		t.Errorf("splitAxes(horizontal, North) = (%q,%q), want (East,West)", a1, a2)
+	}
+
+	a1, a2 = splitAxes(DeviceSplitterVertical, "East")
+	if a1 != "North" || a2 != "South" {
+		t.Errorf("splitAxes(vertical, East) = (%q,%q), want (North,South)", a1, a2)
+	}
+
+	// A splitter whose face doesn't match its axis passes the turmite
+	// straight through instead of splitting it.
+	a1, a2 = splitAxes(DeviceSplitterHorizontal, "East")
+	if a1 != "" || a2 != "" {
+		t.Errorf("splitAxes(horizontal, East) = (%q,%q), want empty strings", a1, a2)
+	}
+}
+
+func TestComputeStepTeleports(t *testing.T) {
+	field := NewField(10)
+	tur := &Turmite{
+		rules: forwardRules(),
+		x:     0, y: 0,
+		face: "East",
+		tiles: TileGrid{
+			Position{1, 0}: Device{Kind: DeviceTeleporter, TeleportX: 5, TeleportY: 5},
+		},
+	}
+	nx, ny, err := tur.computeStep(field)
+	if err != nil {
+		t.Fatalf("computeStep: unexpected error: %v", err)
+	}
+	if nx != 5 || ny != 5 {
+		t.Errorf("computeStep: landed at (%d,%d), want (5,5) via the teleporter", nx, ny)
+	}
+}
+
+func TestComputeStepMirrorReflectsHeading(t *testing.T) {
+	field := NewField(10)
+	tur := &Turmite{
+		rules: forwardRules(),
+		x:     2, y: 2,
+		face: "North",
+		tiles: TileGrid{
+			Position{2, 2}: Device{Kind: DeviceMirrorSlash},
+		},
+		deviceOrder: DeviceBeforeRule,
+	}
+	nx, ny, err := tur.computeStep(field)
+	if err != nil {
+		t.Fatalf("computeStep: unexpected error: %v", err)
+	}
+	// The mirror reflects North->East before the (no-turn) rule is applied,
+	// so the turmite should walk east instead of north.
+	if nx != 3 || ny != 2 {
+		t.Errorf("computeStep: landed at (%d,%d), want (3,2) after the mirror reflects it East", nx, ny)
+	}
+}
+
+func TestColonyStepSplitterSpawnsClone(t *testing.T) {
+	field := NewField(10)
+	tur := &Turmite{
+		rules: forwardRules(),
+		x:     5, y: 5,
+		face: "North",
+		id:   0,
+		tiles: TileGrid{
+			Position{5, 5}: Device{Kind: DeviceSplitterHorizontal},
+		},
+	}
+	colony := Colony{tur}
+	survivors, err := colony.Step(field, CollisionIgnore, nil)
+	if err != nil {
+		t.Fatalf("Step: unexpected error: %v", err)
+	}
+	if len(survivors) != 2 {
+		t.Fatalf("Step: got %d survivors, want 2 (original + splitter clone)", len(survivors))
+	}
+	faces := map[string]bool{}
+	for _, s := range survivors {
+		faces[s.face] = true
+	}
+	if !faces["East"] || !faces["West"] {
+		t.Errorf("Step: survivor faces = %v, want East and West", faces)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Topology decides what happens when a turmite facing face walks off the
+// edge of a size x size field from (x,y). It returns the position the
+// turmite actually ends up at and the facing it ends up with (which Reflect
+// changes when it bounces off a wall).
+type Topology interface {
+	Step(size, x, y int, face string) (nx, ny int, newFace string, err error)
+}
+
+// TorusTopology wraps turmites around to the opposite edge, via PositiveMod.
+// This is the boundary the Clojure turmite reference and most
+// ruletablerepository tables assume, so it is the default.
+type TorusTopology struct{}
+
+func (TorusTopology) Step(size, x, y int, face string) (int, int, string, error) {
+	nx, ny := walk(x, y, face)
+	return PositiveMod(nx, size), PositiveMod(ny, size), face, nil
+}
+
+// ReflectTopology bounces a turmite's heading back off whichever edge it
+// would otherwise cross, leaving it in place for the tick it turns around.
+type ReflectTopology struct{}
+
+func (ReflectTopology) Step(size, x, y int, face string) (int, int, string, error) {
+	nx, ny := walk(x, y, face)
+	newFace := face
+
+	if nx < 0 || nx >= size {
+		nx = x
+		switch face {
+		case "East":
+			newFace = "West"
+		case "West":
+			newFace = "East"
+		}
+	}
+	if ny < 0 || ny >= size {
+		ny = y
+		switch face {
+		case "North":
+			newFace = "South"
+		case "South":
+			newFace = "North"
+		}
+	}
+	return nx, ny, newFace, nil
+}
+
+// BoundedTopology treats the field edge as a wall: a turmite that tries to
+// walk off it gets stuck there and Step reports an error.
+type BoundedTopology struct{}
+
+func (BoundedTopology) Step(size, x, y int, face string) (int, int, string, error) {
+	nx, ny := walk(x, y, face)
+	if nx < 0 || nx >= size || ny < 0 || ny >= size {
+		return x, y, face, fmt.Errorf("turmite stuck at wall (%d,%d) facing %s", x, y, face)
+	}
+	return nx, ny, face, nil
+}
+
+// walk returns the cell one step in front of (x,y) given a facing, with no
+// boundary handling applied.
+func walk(x, y int, face string) (int, int) {
+	switch face {
+	case "North":
+		return x, y - 1
+	case "East":
+		return x + 1, y
+	case "South":
+		return x, y + 1
+	case "West":
+		return x - 1, y
+	default:
+		return x, y
+	}
+}
+
+// TopologyFromString returns a Topology given an English name.
+func TopologyFromString(s string) (Topology, error) {
+	switch strings.ToLower(s) {
+	case "torus", "wrap":
+		return TorusTopology{}, nil
+	case "reflect", "bounce":
+		return ReflectTopology{}, nil
+	case "bounded", "wall":
+		return BoundedTopology{}, nil
+	default:
+		return nil, fmt.Errorf("unknown topology: %s", s)
+	}
+}
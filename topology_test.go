@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestTorusTopologyWraps(t *testing.T) {
+	nx, ny, face, err := TorusTopology{}.Step(5, 4, 0, "East")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nx != 0 || ny != 0 || face != "East" {
+		t.Errorf("got (%d,%d,%s), want (0,0,East)", nx, ny, face)
+	}
+
+	nx, ny, face, err = TorusTopology{}.Step(5, 0, 0, "North")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nx != 0 || ny != 4 || face != "North" {
+		t.Errorf("got (%d,%d,%s), want (0,4,North)", nx, ny, face)
+	}
+}
+
+func TestReflectTopologyBounces(t *testing.T) {
+	nx, ny, face, err := ReflectTopology{}.Step(5, 4, 0, "East")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nx != 4 || ny != 0 || face != "West" {
+		t.Errorf("got (%d,%d,%s), want (4,0,West) after bouncing off the east edge", nx, ny, face)
+	}
+
+	// A non-boundary-crossing move should pass through unaffected.
+	nx, ny, face, err = ReflectTopology{}.Step(5, 2, 2, "East")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nx != 3 || ny != 2 || face != "East" {
+		t.Errorf("got (%d,%d,%s), want (3,2,East)", nx, ny, face)
+	}
+}
+
+func TestBoundedTopologySticks(t *testing.T) {
+	_, _, _, err := BoundedTopology{}.Step(5, 4, 0, "East")
+	if err == nil {
+		t.Fatal("expected an error when walking off a bounded field, got nil")
+	}
+
+	nx, ny, face, err := BoundedTopology{}.Step(5, 2, 2, "South")
+	if err != nil {
+		t.Fatalf("unexpected error on an in-bounds move: %v", err)
+	}
+	if nx != 2 || ny != 3 || face != "South" {
+		t.Errorf("got (%d,%d,%s), want (2,3,South)", nx, ny, face)
+	}
+}
+
+func TestTopologyFromString(t *testing.T) {
+	cases := map[string]Topology{
+		"torus":   TorusTopology{},
+		"wrap":    TorusTopology{},
+		"reflect": ReflectTopology{},
+		"bounce":  ReflectTopology{},
+		"bounded": BoundedTopology{},
+		"wall":    BoundedTopology{},
+	}
+	for name, want := range cases {
+		got, err := TopologyFromString(name)
+		if err != nil {
+			t.Errorf("TopologyFromString(%q): unexpected error: %v", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("TopologyFromString(%q) = %T, want %T", name, got, want)
+		}
+	}
+
+	if _, err := TopologyFromString("spherical"); err == nil {
+		t.Error("TopologyFromString(\"spherical\"): expected error, got nil")
+	}
+}